@@ -0,0 +1,178 @@
+package fsm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// StateEnd is the terminal sentinel state name. An EventStateFunc returns
+// StateEnd as its nextState to signal that the EventMachine has reached a
+// terminal state and should stop being fired.
+const StateEnd = ""
+
+// EventStateFunc handles a single named state of an EventMachine. It is
+// invoked with the context and arguments passed to Fire, and returns the
+// name of the next state to transition to along with the arguments that
+// should be delivered to it.
+type EventStateFunc func(ctx context.Context, args any) (nextState string, nextArgs any, err error)
+
+// BeforeHook is invoked immediately before a transition is applied, once per
+// registered hook, in registration order.
+type BeforeHook func(ctx context.Context, from, event string, args any)
+
+// AfterHook is invoked immediately after a transition has been attempted,
+// once per registered hook, in registration order. err is the error Fire
+// returns for this transition, if any: the state handler's error, or, with a
+// TransitionTable installed, *ErrNoTransition or the Action's error.
+type AfterHook func(ctx context.Context, from, to string, args any, err error)
+
+// EventMachine manages an event-driven finite-state machine.
+//
+// Unlike a finiteStateMachine, which self-transitions by following State
+// functions returned from other State functions, an EventMachine is driven
+// externally: a caller invokes Fire with a named event, and the machine
+// looks up and runs the EventStateFunc registered for its current state.
+// This makes it suitable for request-response workflows, such as chatbots
+// or RPC handlers, where the machine must wait between transitions instead
+// of running a self-driving loop.
+type EventMachine struct {
+	mu sync.Mutex
+
+	states  map[string]EventStateFunc
+	current string
+
+	// initial is the state name passed to NewEventMachine. Validate uses it
+	// as the reachability root instead of current, since current moves as
+	// Fire is called and Validate is meant to analyze declared structure
+	// before the machine runs.
+	initial string
+
+	before []BeforeHook
+	after  []AfterHook
+
+	// table, entryHooks, and exitHooks back the declarative TransitionTable
+	// API. They are nil until UseTransitionTable, OnEnter, or OnExit is
+	// called.
+	table      TransitionTable
+	entryHooks map[string]func(ctx context.Context, args any)
+	exitHooks  map[string]func(ctx context.Context, args any)
+
+	// terminal holds state names marked via MarkTerminal; Done treats
+	// reaching one of them the same as reaching StateEnd.
+	terminal map[string]bool
+}
+
+// NewEventMachine initializes and returns a new EventMachine whose current
+// state is set to the passed initial state name. The initial state does not
+// need to be registered before calling NewEventMachine.
+func NewEventMachine(initial string) *EventMachine {
+	return &EventMachine{
+		states:  make(map[string]EventStateFunc),
+		current: initial,
+		initial: initial,
+	}
+}
+
+// RegisterState associates a state name with the EventStateFunc that
+// handles it. Registering a name a second time replaces its handler.
+func (m *EventMachine) RegisterState(name string, fn EventStateFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[name] = fn
+}
+
+// AddBeforeHook appends a hook that is invoked before every transition, in
+// the order hooks were added.
+func (m *EventMachine) AddBeforeHook(hook BeforeHook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.before = append(m.before, hook)
+}
+
+// AddAfterHook appends a hook that is invoked after every transition, in the
+// order hooks were added.
+func (m *EventMachine) AddAfterHook(hook AfterHook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.after = append(m.after, hook)
+}
+
+// Current returns the name of the machine's current state.
+func (m *EventMachine) Current() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.current
+}
+
+// Done returns true if the machine has reached StateEnd or a state marked
+// terminal with MarkTerminal.
+func (m *EventMachine) Done() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.current == StateEnd || m.terminal[m.current]
+}
+
+// MarkTerminal records name as a terminal state. Once the machine's current
+// state becomes name, Done reports true exactly as it would for StateEnd,
+// without requiring every transition into name to return the StateEnd
+// sentinel instead of name itself — useful when the state's own name needs
+// to survive round-tripping, e.g. through Marshal.
+func (m *EventMachine) MarkTerminal(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.terminal == nil {
+		m.terminal = make(map[string]bool)
+	}
+	m.terminal[name] = true
+}
+
+// Fire drives the machine by invoking the EventStateFunc registered for its
+// current state, passing it the given event and args. On success, the
+// machine's current state becomes the name returned by the handler, which
+// may be StateEnd to terminate the machine.
+//
+// If UseTransitionTable has been called, Fire instead validates the event
+// against the installed TransitionTable and returns *ErrNoTransition if no
+// declared Transition matches the current state and event.
+//
+// Fire returns an error if no handler is registered for the current state,
+// or if the handler itself returns an error. BeforeHook and AfterHook
+// callbacks run around the handler invocation regardless of outcome.
+func (m *EventMachine) Fire(ctx context.Context, event string, args any) error {
+	m.mu.Lock()
+	from := m.current
+	fn, ok := m.states[from]
+	table := m.table
+	before := append([]BeforeHook(nil), m.before...)
+	after := append([]AfterHook(nil), m.after...)
+	m.mu.Unlock()
+
+	if table != nil {
+		return m.fireTable(ctx, event, args)
+	}
+
+	if !ok {
+		return fmt.Errorf("fsm: no state registered with name %q", from)
+	}
+
+	for _, hook := range before {
+		hook(ctx, from, event, args)
+	}
+
+	next, nextArgs, err := fn(ctx, args)
+
+	for _, hook := range after {
+		hook(ctx, from, next, nextArgs, err)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.current = next
+	m.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEventMachineFireTransitionsBetweenRegisteredStates(t *testing.T) {
+	m := NewEventMachine("idle")
+	m.RegisterState("idle", func(ctx context.Context, args any) (string, any, error) {
+		return "running", args, nil
+	})
+	m.RegisterState("running", func(ctx context.Context, args any) (string, any, error) {
+		return StateEnd, nil, nil
+	})
+
+	if err := m.Fire(context.Background(), "start", nil); err != nil {
+		t.Fatalf("Fire() error = %v", err)
+	}
+	if m.Current() != "running" {
+		t.Fatalf("Current() = %q, want %q", m.Current(), "running")
+	}
+	if m.Done() {
+		t.Fatalf("Done() = true, want false while in a non-terminal state")
+	}
+
+	if err := m.Fire(context.Background(), "finish", nil); err != nil {
+		t.Fatalf("Fire() error = %v", err)
+	}
+	if !m.Done() {
+		t.Fatalf("Done() = false after reaching StateEnd, want true")
+	}
+}
+
+func TestEventMachineFireReturnsErrorForUnregisteredState(t *testing.T) {
+	m := NewEventMachine("idle")
+
+	err := m.Fire(context.Background(), "start", nil)
+	if err == nil {
+		t.Fatalf("Fire() error = nil, want an error for an unregistered state")
+	}
+}
+
+func TestEventMachineFirePropagatesHandlerError(t *testing.T) {
+	wantErr := errors.New("boom")
+	m := NewEventMachine("idle")
+	m.RegisterState("idle", func(ctx context.Context, args any) (string, any, error) {
+		return "", nil, wantErr
+	})
+
+	if err := m.Fire(context.Background(), "start", nil); !errors.Is(err, wantErr) {
+		t.Fatalf("Fire() error = %v, want %v", err, wantErr)
+	}
+	if m.Current() != "idle" {
+		t.Fatalf("Current() = %q, want %q (a handler error must not transition the state)", m.Current(), "idle")
+	}
+}
+
+func TestEventMachineHooksRunInRegistrationOrderAroundFire(t *testing.T) {
+	m := NewEventMachine("idle")
+	m.RegisterState("idle", func(ctx context.Context, args any) (string, any, error) {
+		return "running", nil, nil
+	})
+
+	var calls []string
+	m.AddBeforeHook(func(ctx context.Context, from, event string, args any) {
+		calls = append(calls, "before1:"+from+":"+event)
+	})
+	m.AddBeforeHook(func(ctx context.Context, from, event string, args any) {
+		calls = append(calls, "before2:"+from+":"+event)
+	})
+	m.AddAfterHook(func(ctx context.Context, from, to string, args any, err error) {
+		calls = append(calls, "after1:"+from+":"+to)
+	})
+	m.AddAfterHook(func(ctx context.Context, from, to string, args any, err error) {
+		calls = append(calls, "after2:"+from+":"+to)
+	})
+
+	if err := m.Fire(context.Background(), "start", nil); err != nil {
+		t.Fatalf("Fire() error = %v", err)
+	}
+
+	want := []string{"before1:idle:start", "before2:idle:start", "after1:idle:running", "after2:idle:running"}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("calls[%d] = %q, want %q", i, calls[i], want[i])
+		}
+	}
+}
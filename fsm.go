@@ -2,31 +2,27 @@
 package fsm
 
 import (
-	"bytes"
+	"context"
 	"errors"
 	"fmt"
-	"os"
 	"reflect"
 	"runtime"
-	"strconv"
 	"strings"
-	"text/template"
+	"sync"
+	"time"
 )
 
 const (
-	dotFileTemplate = `strict digraph stategraph {
-	start [shape="circle", color="green", style="filled"]
-	end   [shape="circle", color="red", style="filled"]
-	{{range $vertex, $edges := .}}{{range $edge, $steps := $edges}}
-		{{$vertex}} -> {{$edge}} [label=" {{joinInt $steps ","}}",fontsize=10]
-	{{end}}{{end}}
-}`
-	dotFileName      = "dot_graph"
-	dotFileExtension = "gv"
-	startID          = "start"
-	endID            = "end"
+	startID   = "start"
+	endID     = "end"
+	timeoutID = "timeout"
+	errorID   = "error"
 )
 
+// errStateTimeout wraps the error RunContext returns when a State exceeds
+// the duration configured for it with WithStateTimeout.
+var errStateTimeout = errors.New("fsm: state timed out")
+
 // State is a function that handles a machine state and returns the next machine state.
 //
 // A function that participates as a state in the finite-state machine must be of the State type.
@@ -34,9 +30,41 @@ type State func() (State, error)
 
 // A finiteStateMachine manages a finite-state machine.
 type finiteStateMachine struct {
-	start   State
-	step    int64
-	dotFile *os.File
+	// mu guards every field below except start, so that observers,
+	// Snapshot, Pause, and Resume can be called safely from a goroutine
+	// other than the one running the machine. start is read and written
+	// without mu, by RunContext and by the package-level Resume.
+	mu sync.Mutex
+
+	start State
+	step  int64
+
+	// timeouts maps a state name to the maximum duration RunContext will
+	// let that state run before recording a synthetic transition to the
+	// timeout sentinel node and returning an error.
+	timeouts map[string]time.Duration
+
+	// pauseGate, when non-nil, blocks the run loop between transitions
+	// until Resume closes it.
+	pauseGate chan struct{}
+
+	// observers are notified of lifecycle and transition events as the
+	// machine runs. LogStateTransitionGraph registers a DotObserver here;
+	// callers may register additional Observers via AddObserver.
+	observers []Observer
+
+	// currentStateName is the registry name of the state the machine is
+	// currently in, used by Snapshot so Resume can resolve it back to a
+	// callable State.
+	currentStateName string
+
+	// store, when set via Checkpoint, receives a Snapshot after every
+	// transition so a killed process can Resume mid-flow.
+	store Store
+
+	// checkpointErr holds the error from the most recent Checkpoint save,
+	// if any, and is surfaced as the error returned from Run.
+	checkpointErr error
 
 	// adjacencyMap tracks each state and transition as a vertex to edge pair.
 	// Each vertex to edge pair also records the step in which the transition happened.
@@ -57,22 +85,93 @@ func NewMachine() *finiteStateMachine {
 // the finite-state machine transitions from state to state.
 // Run will return an error if an error is returned from any State function.
 // Run will return nil if a terminal State is reached.
+//
+// Passing a nil startState resumes a machine primed by Resume, starting
+// from the State it resolved from the Snapshot's CurrentState. Passing nil
+// on a machine that has neither been primed by Resume nor run before returns
+// an error; on a machine that has run before, it starts again from the
+// State that run started from.
+//
+// Run is equivalent to RunContext(context.Background(), startState).
 func (fsm *finiteStateMachine) Run(startState State) error {
+	return fsm.RunContext(context.Background(), startState)
+}
+
+// RunContext is Run, but honors ctx: if ctx is canceled or its deadline
+// expires between transitions, RunContext stops promptly and returns
+// ctx.Err(). Canceling ctx does not interrupt a state function already in
+// progress, since a State has no way to observe ctx itself.
+func (fsm *finiteStateMachine) RunContext(ctx context.Context, startState State) error {
+	if startState == nil {
+		startState = fsm.start
+	}
 	if startState == nil {
 		return errors.New("start must not be nil")
 	}
 
 	fsm.start = startState
-	err := fsm.run()
+	fsm.notifyStart(getFunctionName(startState))
+	err := fsm.run(ctx)
+	fsm.notifyTerminate(err)
 
-	if fsm.isTracing() {
-		err := fsm.adjacencyMapToDotGraph()
-		if err != nil {
-			return err
-		}
+	return err
+}
+
+// WithStateTimeout configures the maximum duration that the state
+// registered under name may run for. If it is exceeded, RunContext records
+// a synthetic transition to the timeout sentinel node and returns an error
+// wrapping errStateTimeout.
+func (fsm *finiteStateMachine) WithStateTimeout(name string, d time.Duration) {
+	fsm.mu.Lock()
+	defer fsm.mu.Unlock()
+
+	if fsm.timeouts == nil {
+		fsm.timeouts = make(map[string]time.Duration)
 	}
+	fsm.timeouts[name] = d
+}
 
-	return err
+// Pause halts the machine between transitions until Resume is called. A
+// transition already in progress runs to completion; the machine blocks
+// before starting the next one.
+func (fsm *finiteStateMachine) Pause() {
+	fsm.mu.Lock()
+	defer fsm.mu.Unlock()
+
+	if fsm.pauseGate == nil {
+		fsm.pauseGate = make(chan struct{})
+	}
+}
+
+// Resume releases a machine halted by Pause. It is a no-op if the machine
+// isn't paused.
+func (fsm *finiteStateMachine) Resume() {
+	fsm.mu.Lock()
+	defer fsm.mu.Unlock()
+
+	if fsm.pauseGate != nil {
+		close(fsm.pauseGate)
+		fsm.pauseGate = nil
+	}
+}
+
+// waitIfPaused blocks until Resume is called if the machine is currently
+// paused, or until ctx is done.
+func (fsm *finiteStateMachine) waitIfPaused(ctx context.Context) error {
+	fsm.mu.Lock()
+	gate := fsm.pauseGate
+	fsm.mu.Unlock()
+
+	if gate == nil {
+		return nil
+	}
+
+	select {
+	case <-gate:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // LogStateTransitionGraph enables tracing of states and transitions for the life of the finite-state machine.
@@ -82,71 +181,133 @@ func (fsm *finiteStateMachine) Run(startState State) error {
 // finite-state machine.
 //
 // If the passed string is empty, the file will be logged to the directory that the program was executed in.
+//
+// LogStateTransitionGraph is sugar for constructing a DotObserver and
+// passing it to AddObserver.
 func (fsm *finiteStateMachine) LogStateTransitionGraph(path string) error {
-	// If no path supplied, log to the directory that the program was executed in
-	if path == "" {
-		path = "."
-	}
-	filePath := fmt.Sprintf("%s/%s.%s", strings.TrimSuffix(path, "/"), dotFileName,
-		dotFileExtension)
-
-	file, err := os.Create(filePath)
+	obs, err := NewDotObserver(path)
 	if err != nil {
 		return err
 	}
 
-	fsm.dotFile = file
+	fsm.AddObserver(obs)
 	return nil
 }
 
-// run starts the finite-state machine and records state transitions.
-func (fsm *finiteStateMachine) run() error {
+// run starts the finite-state machine and records state transitions,
+// honoring ctx cancellation and per-state timeouts between transitions.
+func (fsm *finiteStateMachine) run(ctx context.Context) error {
 	var err error
 	var currentState, nextState State
 	currentState = fsm.start
 	nextState = nil
 
 	// Continue to process steps while not in a terminal state and an error hasn't occurred
-	fsm.recordStateTransition(startID, getFunctionName(currentState))
+	fsm.setCurrentStateName(getFunctionName(currentState))
+	fsm.recordStateTransition(startID, getFunctionName(currentState), nil)
+	if err == nil {
+		if cpErr := fsm.checkpointError(); cpErr != nil {
+			return cpErr
+		}
+	}
+
 	for currentState != nil && err == nil {
-		nextState, err = currentState()
-		fsm.recordStateTransition(getFunctionName(currentState), getFunctionName(nextState))
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err := fsm.waitIfPaused(ctx); err != nil {
+			return err
+		}
+
+		name := getFunctionName(currentState)
+		nextState, err = fsm.invoke(ctx, name, currentState)
+		if errors.Is(err, errStateTimeout) {
+			fsm.recordStateTransition(name, timeoutID, err)
+			return err
+		}
+		if err != nil {
+			// The state errored out, or ctx was canceled while it was
+			// still running, so nextState is not a real transition target
+			// (invoke returns it as nil on cancellation). Recording it
+			// under endID would make an aborted run look like it reached
+			// a terminal state.
+			fsm.recordStateTransition(name, errorID, err)
+			return err
+		}
+
+		// setCurrentStateName must run before recordStateTransition, since
+		// recordStateTransition may persist a Checkpoint mid-call: a
+		// Snapshot taken there needs to already reflect the state the
+		// machine is transitioning into, not the one it just left.
+		fsm.setCurrentStateName(getFunctionName(nextState))
+		fsm.recordStateTransition(name, getFunctionName(nextState), err)
 		currentState = nextState
+		err = fsm.checkpointError()
 	}
 
 	return err
 }
 
-// adjacencyMapToDotGraph writes the in-memory representation of the directed graph to a DOT formatted string.
-func (fsm *finiteStateMachine) adjacencyMapToDotGraph() error {
-	// Set up custom template functions
-	funcMap := template.FuncMap{
-		"joinInt": fsm.joinInt,
+// invoke runs state, racing it against the duration configured for name by
+// WithStateTimeout, if any. The state function has no way to observe ctx or
+// the timeout itself, so a timed-out or canceled call leaves it running in
+// the background; its eventual result is discarded.
+func (fsm *finiteStateMachine) invoke(ctx context.Context, name string, state State) (State, error) {
+	fsm.mu.Lock()
+	d, ok := fsm.timeouts[name]
+	fsm.mu.Unlock()
+
+	if !ok {
+		return state()
 	}
 
-	// Parse the template
-	tmpl, err := template.New(dotFileName).Funcs(funcMap).Parse(dotFileTemplate)
-	if err != nil {
-		return err
+	type result struct {
+		next State
+		err  error
 	}
+	done := make(chan result, 1)
+	go func() {
+		next, err := state()
+		done <- result{next, err}
+	}()
 
-	// Execute the template
-	err = tmpl.Execute(fsm.dotFile, fsm.adjacencyMap)
-	if err != nil {
-		return err
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case r := <-done:
+		return r.next, r.err
+	case <-timer.C:
+		return nil, fmt.Errorf("%w: state %q exceeded %s", errStateTimeout, name, d)
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
+}
 
-	return nil
+// setCurrentStateName records the name of the state the machine is
+// currently in under mu, for Snapshot to read safely from another
+// goroutine.
+func (fsm *finiteStateMachine) setCurrentStateName(name string) {
+	fsm.mu.Lock()
+	fsm.currentStateName = name
+	fsm.mu.Unlock()
 }
 
-// recordStateTransition records a state transition in the finite-state machine.
-func (fsm *finiteStateMachine) recordStateTransition(curr, next string) {
-	if !fsm.isTracing() {
-		return
-	}
+// checkpointError returns the error from the most recent Checkpoint save, if any.
+func (fsm *finiteStateMachine) checkpointError() error {
+	fsm.mu.Lock()
+	defer fsm.mu.Unlock()
+	return fsm.checkpointErr
+}
+
+// recordStateTransition records a state transition in the finite-state
+// machine's adjacency map and notifies any registered observers.
+func (fsm *finiteStateMachine) recordStateTransition(curr, next string, err error) {
+	fsm.mu.Lock()
 
 	// Increase the step count
 	fsm.step++
+	step := fsm.step
 
 	// Add state vertex and edge
 	_, haveVertex := fsm.adjacencyMap[curr]
@@ -166,11 +327,23 @@ func (fsm *finiteStateMachine) recordStateTransition(curr, next string) {
 
 	// Append the step count into the edge steps
 	edgeMap[next] = append(edgeSteps, fsm.step)
-}
 
-// isTracing returns true if the finite-state machine has been configured to trace states and transitions.
-func (fsm *finiteStateMachine) isTracing() bool {
-	return fsm.dotFile != nil
+	store := fsm.store
+
+	fsm.mu.Unlock()
+
+	fsm.notifyTransition(curr, next, step, err)
+
+	if store != nil {
+		snap, snapErr := fsm.Snapshot()
+		if snapErr == nil {
+			snapErr = store.Save(snap)
+		}
+
+		fsm.mu.Lock()
+		fsm.checkpointErr = snapErr
+		fsm.mu.Unlock()
+	}
 }
 
 // getFunctionName returns the name of the passed State function.
@@ -185,12 +358,3 @@ func getFunctionName(f State) string {
 	funcName := funcSegments[len(funcSegments)-1]
 	return strings.Split(funcName, ".")[1]
 }
-
-// joinInt joins the passed array of integers into a string delimited by the passed delimiter
-func (fsm *finiteStateMachine) joinInt(steps []int64, delimiter string) string {
-	stepBuf := bytes.Buffer{}
-	for _, step := range steps {
-		stepBuf.WriteString(fmt.Sprintf("%s%s", strconv.FormatInt(step, 10), delimiter))
-	}
-	return strings.TrimSuffix(stepBuf.String(), delimiter)
-}
@@ -0,0 +1,174 @@
+// Package fsmdef parses a small text DSL describing an event-driven state
+// graph and compiles it into a runnable fsm.EventMachine. This lets
+// non-Go operators author state graphs — workflows, IVR menus, game AI —
+// as data, with the Go side supplying only the leaf handler functions that
+// do the real work.
+//
+// The DSL has three directives, one per line:
+//
+//	state Wander
+//	on spotted -> Chase
+//	on pellet -> Flee
+//	terminal Dead
+//
+// Each `on` directive applies to the most recently declared `state` or
+// `terminal` block. Blank lines and lines starting with '#' are ignored.
+package fsmdef
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	fsm "github.com/austingebauer/go-fsm"
+)
+
+// Transition is a single `on EVENT -> TARGET` directive declared under a state.
+type Transition struct {
+	Event  string
+	Target string
+}
+
+// StateDef is a single `state NAME` or `terminal NAME` block and the
+// transitions declared under it.
+type StateDef struct {
+	Name        string
+	Terminal    bool
+	Transitions []Transition
+}
+
+// IR is the parsed, intermediate representation of an fsmdef program: an
+// ordered list of state definitions. Compile turns an IR into a live
+// fsm.EventMachine; Marshal turns it back into DSL source.
+type IR struct {
+	States []StateDef
+}
+
+// Handler supplies the leaf business logic that runs when a DSL-declared
+// state processes an event. The DSL itself decides which event leads to
+// which state; Handler only does the work, and an error it returns is
+// returned from fsm.EventMachine.Fire without transitioning.
+type Handler func(ctx context.Context, args any) error
+
+// Parse reads fsmdef source and returns its intermediate representation.
+func Parse(src string) (*IR, error) {
+	ir := &IR{}
+	var current *StateDef
+
+	scanner := bufio.NewScanner(strings.NewReader(src))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "state":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("fsmdef: line %d: expected 'state NAME'", lineNum)
+			}
+			ir.States = append(ir.States, StateDef{Name: fields[1]})
+			current = &ir.States[len(ir.States)-1]
+
+		case "terminal":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("fsmdef: line %d: expected 'terminal NAME'", lineNum)
+			}
+			ir.States = append(ir.States, StateDef{Name: fields[1], Terminal: true})
+			current = &ir.States[len(ir.States)-1]
+
+		case "on":
+			if current == nil {
+				return nil, fmt.Errorf("fsmdef: line %d: 'on' directive outside of a state block", lineNum)
+			}
+			if len(fields) != 4 || fields[2] != "->" {
+				return nil, fmt.Errorf("fsmdef: line %d: expected 'on EVENT -> TARGET'", lineNum)
+			}
+			current.Transitions = append(current.Transitions, Transition{Event: fields[1], Target: fields[3]})
+
+		default:
+			return nil, fmt.Errorf("fsmdef: line %d: unknown directive %q", lineNum, fields[0])
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return ir, nil
+}
+
+// Compile turns ir into a runnable fsm.EventMachine, starting in the first
+// declared state. Every `on EVENT -> TARGET` directive becomes an
+// fsm.Transition; handlers, keyed by state name, supplies the Action run for
+// each state's transitions, terminal states included. A missing handler is
+// not an error — the state's transitions simply carry no Action.
+//
+// Each `terminal NAME` state is registered with the machine via
+// MarkTerminal, so that once the machine reaches it, Done reports true. A
+// terminal block with no `on` directives has no outgoing transitions, so
+// firing a further event there returns *fsm.ErrNoTransition the same way it
+// would for any other state with no matching transition; `on` directives
+// declared under a terminal block are compiled like any others.
+func Compile(ir *IR, handlers map[string]Handler) (*fsm.EventMachine, error) {
+	if len(ir.States) == 0 {
+		return nil, errors.New("fsmdef: no states declared")
+	}
+
+	m := fsm.NewEventMachine(ir.States[0].Name)
+
+	var table fsm.TransitionTable
+	for _, state := range ir.States {
+		if state.Terminal {
+			m.MarkTerminal(state.Name)
+		}
+
+		var action func(ctx context.Context, args any) error
+		if handler, ok := handlers[state.Name]; ok {
+			action = func(ctx context.Context, args any) error {
+				return handler(ctx, args)
+			}
+		}
+
+		for _, t := range state.Transitions {
+			table = append(table, fsm.Transition{
+				From:   state.Name,
+				Event:  t.Event,
+				To:     t.Target,
+				Action: action,
+			})
+		}
+	}
+
+	m.UseTransitionTable(table)
+	return m, nil
+}
+
+// Marshal regenerates fsmdef DSL source from ir. It is the IR-side half of
+// the round trip: Parse produces an *IR, Marshal turns an *IR back into
+// source. To regenerate DSL source from an already-compiled
+// *fsm.EventMachine — without keeping the original *IR around — use the
+// machine's own Marshal method instead, which Compile's MarkTerminal and
+// UseTransitionTable calls keep in sync with the declared structure.
+func Marshal(ir *IR) string {
+	var b strings.Builder
+	for i, state := range ir.States {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		if state.Terminal {
+			fmt.Fprintf(&b, "terminal %s\n", state.Name)
+		} else {
+			fmt.Fprintf(&b, "state %s\n", state.Name)
+		}
+		for _, t := range state.Transitions {
+			fmt.Fprintf(&b, "on %s -> %s\n", t.Event, t.Target)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,76 @@
+package fsmdef
+
+import (
+	"context"
+	"testing"
+)
+
+const pacmanDSL = `
+state Wander
+on spotted -> Chase
+
+state Chase
+on eaten -> Dead
+
+terminal Dead
+`
+
+func TestCompileReachingTerminalStateMarksDone(t *testing.T) {
+	ir, err := Parse(pacmanDSL)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	m, err := Compile(ir, nil)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	if err := m.Fire(context.Background(), "spotted", nil); err != nil {
+		t.Fatalf("Fire(spotted) error = %v", err)
+	}
+	if err := m.Fire(context.Background(), "eaten", nil); err != nil {
+		t.Fatalf("Fire(eaten) error = %v", err)
+	}
+
+	if m.Current() != "Dead" {
+		t.Fatalf("Current() = %q, want %q", m.Current(), "Dead")
+	}
+	if !m.Done() {
+		t.Fatalf("Done() = false after reaching terminal state %q, want true", m.Current())
+	}
+}
+
+func TestMarshalRoundTripsTerminalState(t *testing.T) {
+	ir, err := Parse(pacmanDSL)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	m, err := Compile(ir, nil)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	out := m.Marshal()
+
+	reparsed, err := Parse(out)
+	if err != nil {
+		t.Fatalf("Parse(Marshal()) error = %v\nmarshaled:\n%s", err, out)
+	}
+
+	remachine, err := Compile(reparsed, nil)
+	if err != nil {
+		t.Fatalf("Compile(Parse(Marshal())) error = %v", err)
+	}
+
+	if err := remachine.Fire(context.Background(), "spotted", nil); err != nil {
+		t.Fatalf("Fire(spotted) on round-tripped machine error = %v", err)
+	}
+	if err := remachine.Fire(context.Background(), "eaten", nil); err != nil {
+		t.Fatalf("Fire(eaten) on round-tripped machine error = %v", err)
+	}
+	if !remachine.Done() {
+		t.Fatalf("round-tripped machine Done() = false after reaching Dead, want true")
+	}
+}
@@ -0,0 +1,201 @@
+// Package graph provides a small directed-graph representation that backs
+// the event-driven machine's static validation of state reachability and
+// cycles. Its Dot method renders a Graph as DOT text; the fsm package's
+// DotObserver does not use it and renders its trace output itself.
+package graph
+
+import "sort"
+
+// Graph is a directed graph represented as an adjacency list from vertex
+// name to the set of vertices it has an edge to.
+type Graph struct {
+	adjacency map[string]map[string]bool
+}
+
+// New returns an empty Graph.
+func New() *Graph {
+	return &Graph{adjacency: make(map[string]map[string]bool)}
+}
+
+// AddVertex adds name to the graph if it is not already present. It is a
+// no-op if name already has at least one edge recorded.
+func (g *Graph) AddVertex(name string) {
+	if _, ok := g.adjacency[name]; !ok {
+		g.adjacency[name] = make(map[string]bool)
+	}
+}
+
+// AddEdge records a directed edge from -> to, adding both vertices to the
+// graph if they are not already present.
+func (g *Graph) AddEdge(from, to string) {
+	g.AddVertex(from)
+	g.AddVertex(to)
+	g.adjacency[from][to] = true
+}
+
+// Vertices returns every vertex in the graph, sorted for determinism.
+func (g *Graph) Vertices() []string {
+	vertices := make([]string, 0, len(g.adjacency))
+	for v := range g.adjacency {
+		vertices = append(vertices, v)
+	}
+	sort.Strings(vertices)
+	return vertices
+}
+
+// Edges returns the vertices that from has an outgoing edge to, sorted for
+// determinism.
+func (g *Graph) Edges(from string) []string {
+	edges := make([]string, 0, len(g.adjacency[from]))
+	for to := range g.adjacency[from] {
+		edges = append(edges, to)
+	}
+	sort.Strings(edges)
+	return edges
+}
+
+// BFS returns the vertices reachable from start, in breadth-first order.
+// start is included first if it is part of the graph.
+func (g *Graph) BFS(start string) []string {
+	if _, ok := g.adjacency[start]; !ok {
+		return nil
+	}
+
+	visited := map[string]bool{start: true}
+	order := []string{start}
+	queue := []string{start}
+
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, next := range g.Edges(v) {
+			if !visited[next] {
+				visited[next] = true
+				order = append(order, next)
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return order
+}
+
+// DFS returns the vertices reachable from start, in depth-first order.
+// start is included first if it is part of the graph.
+func (g *Graph) DFS(start string) []string {
+	if _, ok := g.adjacency[start]; !ok {
+		return nil
+	}
+
+	visited := make(map[string]bool)
+	var order []string
+
+	var visit func(v string)
+	visit = func(v string) {
+		if visited[v] {
+			return
+		}
+		visited[v] = true
+		order = append(order, v)
+		for _, next := range g.Edges(v) {
+			visit(next)
+		}
+	}
+	visit(start)
+
+	return order
+}
+
+// SCC returns the strongly connected components of g, computed with
+// Tarjan's algorithm. Each inner slice holds the vertices of one component;
+// a component of size one with no self-loop is a trivial, acyclic vertex.
+func (g *Graph) SCC() [][]string {
+	index := 0
+	indices := make(map[string]int)
+	lowlinks := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+
+	var strongConnect func(v string)
+	strongConnect = func(v string) {
+		indices[v] = index
+		lowlinks[v] = index
+		index++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range g.Edges(v) {
+			if _, seen := indices[w]; !seen {
+				strongConnect(w)
+				if lowlinks[w] < lowlinks[v] {
+					lowlinks[v] = lowlinks[w]
+				}
+			} else if onStack[w] {
+				if indices[w] < lowlinks[v] {
+					lowlinks[v] = indices[w]
+				}
+			}
+		}
+
+		if lowlinks[v] == indices[v] {
+			var component []string
+			for {
+				n := len(stack) - 1
+				w := stack[n]
+				stack = stack[:n]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+
+	for _, v := range g.Vertices() {
+		if _, seen := indices[v]; !seen {
+			strongConnect(v)
+		}
+	}
+
+	return components
+}
+
+// HasExit reports whether any vertex in the set component has an outgoing
+// edge to a vertex outside of component. It is used to detect cycles that
+// have no way to leave themselves.
+func (g *Graph) HasExit(component []string) bool {
+	inComponent := make(map[string]bool, len(component))
+	for _, v := range component {
+		inComponent[v] = true
+	}
+
+	for _, v := range component {
+		for _, to := range g.Edges(v) {
+			if !inComponent[to] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Dot renders g as a DOT graph description language string named name.
+// edgeLabel, if non-nil, is called for every edge to produce its label.
+func (g *Graph) Dot(name string, edgeLabel func(from, to string) string) string {
+	out := "strict digraph " + name + " {\n"
+	for _, from := range g.Vertices() {
+		for _, to := range g.Edges(from) {
+			label := ""
+			if edgeLabel != nil {
+				label = edgeLabel(from, to)
+			}
+			out += "\t" + from + " -> " + to + " [label=\" " + label + "\",fontsize=10]\n"
+		}
+	}
+	out += "}"
+	return out
+}
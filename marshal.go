@@ -0,0 +1,78 @@
+package fsm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Marshal renders the machine's installed TransitionTable and terminal
+// states as fsmdef DSL source: a `state NAME` or `terminal NAME` block per
+// state, followed by its `on EVENT -> TARGET` transitions in declared
+// order. Unlike fsmdef.Marshal(ir), which needs the original *IR, Marshal
+// works directly off the compiled machine: it writes every state that is the
+// From of a declared Transition, in first-declared order, followed by the
+// remaining terminal states sorted by name. A non-terminal state that is the
+// From of no Transition is not retained by the machine and is not written.
+func (m *EventMachine) Marshal() string {
+	m.mu.Lock()
+	table := append(TransitionTable(nil), m.table...)
+	terminal := make(map[string]bool, len(m.terminal))
+	for name := range m.terminal {
+		terminal[name] = true
+	}
+	m.mu.Unlock()
+
+	order, transitions := groupTransitionsByFrom(table)
+
+	seen := make(map[string]bool, len(order))
+	for _, name := range order {
+		seen[name] = true
+	}
+
+	var terminalOnly []string
+	for name := range terminal {
+		if !seen[name] {
+			terminalOnly = append(terminalOnly, name)
+		}
+	}
+	sort.Strings(terminalOnly)
+	order = append(order, terminalOnly...)
+
+	var b strings.Builder
+	for i, name := range order {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		if terminal[name] {
+			fmt.Fprintf(&b, "terminal %s\n", name)
+		} else {
+			fmt.Fprintf(&b, "state %s\n", name)
+		}
+		for _, t := range transitions[name] {
+			fmt.Fprintf(&b, "on %s -> %s\n", t.Event, t.To)
+		}
+	}
+
+	return b.String()
+}
+
+// groupTransitionsByFrom buckets table by its From state, returning the
+// From names in first-seen (declaration) order alongside each name's
+// transitions.
+func groupTransitionsByFrom(table TransitionTable) ([]string, map[string][]Transition) {
+	groups := make(map[string][]Transition)
+	var order []string
+	seen := make(map[string]bool)
+
+	for _, t := range table {
+		if !seen[t.From] {
+			seen[t.From] = true
+			order = append(order, t.From)
+		}
+		groups[t.From] = append(groups[t.From], t)
+	}
+
+	return order, groups
+}
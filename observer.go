@@ -0,0 +1,276 @@
+package fsm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	dotFileTemplate  = `strict digraph stategraph {`
+	dotFileName      = "dot_graph"
+	dotFileExtension = "gv"
+)
+
+// Observer receives lifecycle and transition events from a finite-state
+// machine as it runs. Built-in observers cover DOT-graph tracing
+// (DotObserver), JSON-lines logging (JSONObserver), log/slog logging
+// (SlogObserver), and an in-memory ring buffer useful in tests
+// (RingBufferObserver). Implementing Observer elsewhere allows wiring in
+// things like Prometheus metrics or OpenTelemetry spans without changing
+// core machine code.
+type Observer interface {
+	// OnStart is called once, with the name of the start state, before the
+	// machine's first transition.
+	OnStart(start string)
+
+	// OnTransition is called after every state transition, including the
+	// transition into the start state and into the terminal end state. err
+	// is non-nil only on the transition that ends a failed run: the error
+	// returned by the state function, the timeout error of a state that
+	// exceeded its WithStateTimeout duration, or ctx's error.
+	OnTransition(from, to string, step int64, err error)
+
+	// OnTerminate is called once, after the machine stops running, with the
+	// error Run will return, if any.
+	OnTerminate(err error)
+}
+
+// AddObserver registers obs to receive lifecycle and transition events.
+// Observers are notified in the order they were added.
+func (fsm *finiteStateMachine) AddObserver(obs Observer) {
+	fsm.mu.Lock()
+	defer fsm.mu.Unlock()
+	fsm.observers = append(fsm.observers, obs)
+}
+
+// observerSnapshot returns a copy of the registered observers, so they can
+// be notified without holding mu.
+func (fsm *finiteStateMachine) observerSnapshot() []Observer {
+	fsm.mu.Lock()
+	defer fsm.mu.Unlock()
+	return append([]Observer(nil), fsm.observers...)
+}
+
+// notifyStart calls OnStart on every registered observer, in order.
+func (fsm *finiteStateMachine) notifyStart(start string) {
+	for _, obs := range fsm.observerSnapshot() {
+		obs.OnStart(start)
+	}
+}
+
+// notifyTransition calls OnTransition on every registered observer, in order.
+func (fsm *finiteStateMachine) notifyTransition(from, to string, step int64, err error) {
+	for _, obs := range fsm.observerSnapshot() {
+		obs.OnTransition(from, to, step, err)
+	}
+}
+
+// notifyTerminate calls OnTerminate on every registered observer, in order.
+func (fsm *finiteStateMachine) notifyTerminate(err error) {
+	for _, obs := range fsm.observerSnapshot() {
+		obs.OnTerminate(err)
+	}
+}
+
+// DotObserver accumulates transitions and, on OnTerminate, writes them out
+// as a DOT graph description language file. It is the Observer equivalent
+// of the finite-state machine's original hard-coded dotFile tracing.
+type DotObserver struct {
+	mu           sync.Mutex
+	file         *os.File
+	adjacencyMap map[string]map[string][]int64
+}
+
+// NewDotObserver creates the DOT file 'dot_graph.gv' in path (the current
+// directory if path is empty) and returns a DotObserver that writes the
+// recorded transition graph to it when the machine terminates.
+func NewDotObserver(path string) (*DotObserver, error) {
+	if path == "" {
+		path = "."
+	}
+	filePath := fmt.Sprintf("%s/%s.%s", strings.TrimSuffix(path, "/"), dotFileName, dotFileExtension)
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DotObserver{file: file, adjacencyMap: make(map[string]map[string][]int64)}, nil
+}
+
+// OnStart implements Observer. The start state is also reported via the
+// first OnTransition call, so OnStart does nothing here.
+func (o *DotObserver) OnStart(start string) {}
+
+// OnTransition implements Observer by recording the transition for later rendering.
+func (o *DotObserver) OnTransition(from, to string, step int64, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.adjacencyMap[from] == nil {
+		o.adjacencyMap[from] = make(map[string][]int64)
+	}
+	o.adjacencyMap[from][to] = append(o.adjacencyMap[from][to], step)
+}
+
+// OnTerminate implements Observer by rendering every recorded transition as
+// a DOT graph description language string and writing it to the file
+// opened by NewDotObserver.
+func (o *DotObserver) OnTerminate(err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var buf bytes.Buffer
+	buf.WriteString(dotFileTemplate + "\n")
+	buf.WriteString("\tstart [shape=\"circle\", color=\"green\", style=\"filled\"]\n")
+	buf.WriteString("\tend   [shape=\"circle\", color=\"red\", style=\"filled\"]\n")
+
+	for vertex, edges := range o.adjacencyMap {
+		for edge, steps := range edges {
+			buf.WriteString(fmt.Sprintf("\t%s -> %s [label=\" %s\",fontsize=10]\n", vertex, edge, joinInt(steps, ",")))
+		}
+	}
+	buf.WriteString("}")
+
+	_, _ = o.file.Write(buf.Bytes())
+}
+
+// joinInt joins the passed array of integers into a string delimited by the passed delimiter.
+func joinInt(steps []int64, delimiter string) string {
+	stepBuf := bytes.Buffer{}
+	for _, step := range steps {
+		stepBuf.WriteString(fmt.Sprintf("%s%s", strconv.FormatInt(step, 10), delimiter))
+	}
+	return strings.TrimSuffix(stepBuf.String(), delimiter)
+}
+
+// TransitionEvent is the structured record emitted by JSONObserver and held
+// by RingBufferObserver for each transition.
+type TransitionEvent struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Step int64  `json:"step"`
+	Err  string `json:"error,omitempty"`
+}
+
+// JSONObserver writes one JSON object per transition to w, suitable for
+// machine-readable trace files.
+type JSONObserver struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONObserver returns a JSONObserver that writes JSON-lines to w.
+func NewJSONObserver(w io.Writer) *JSONObserver {
+	return &JSONObserver{enc: json.NewEncoder(w)}
+}
+
+// OnStart implements Observer. JSONObserver only emits transitions.
+func (o *JSONObserver) OnStart(start string) {}
+
+// OnTransition implements Observer by encoding the transition as a JSON line.
+func (o *JSONObserver) OnTransition(from, to string, step int64, err error) {
+	event := TransitionEvent{From: from, To: to, Step: step}
+	if err != nil {
+		event.Err = err.Error()
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_ = o.enc.Encode(event)
+}
+
+// OnTerminate implements Observer. JSONObserver only emits transitions.
+func (o *JSONObserver) OnTerminate(err error) {}
+
+// SlogObserver logs lifecycle and transition events through a log/slog
+// Logger.
+type SlogObserver struct {
+	logger *slog.Logger
+}
+
+// NewSlogObserver returns a SlogObserver that logs through logger. If
+// logger is nil, slog.Default() is used.
+func NewSlogObserver(logger *slog.Logger) *SlogObserver {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogObserver{logger: logger}
+}
+
+// OnStart implements Observer.
+func (o *SlogObserver) OnStart(start string) {
+	o.logger.Info("fsm: start", "state", start)
+}
+
+// OnTransition implements Observer.
+func (o *SlogObserver) OnTransition(from, to string, step int64, err error) {
+	if err != nil {
+		o.logger.Error("fsm: transition", "from", from, "to", to, "step", step, "error", err)
+		return
+	}
+	o.logger.Info("fsm: transition", "from", from, "to", to, "step", step)
+}
+
+// OnTerminate implements Observer.
+func (o *SlogObserver) OnTerminate(err error) {
+	if err != nil {
+		o.logger.Error("fsm: terminate", "error", err)
+		return
+	}
+	o.logger.Info("fsm: terminate")
+}
+
+// RingBufferObserver keeps the most recent transitions in memory, useful
+// for asserting on machine behavior in tests without touching the
+// filesystem.
+type RingBufferObserver struct {
+	mu     sync.Mutex
+	size   int
+	events []TransitionEvent
+}
+
+// NewRingBufferObserver returns a RingBufferObserver that retains at most
+// size of the most recent transitions.
+func NewRingBufferObserver(size int) *RingBufferObserver {
+	return &RingBufferObserver{size: size}
+}
+
+// OnStart implements Observer. RingBufferObserver only retains transitions.
+func (o *RingBufferObserver) OnStart(start string) {}
+
+// OnTransition implements Observer by appending the transition to the ring
+// buffer, evicting the oldest entry once size is exceeded.
+func (o *RingBufferObserver) OnTransition(from, to string, step int64, err error) {
+	event := TransitionEvent{From: from, To: to, Step: step}
+	if err != nil {
+		event.Err = err.Error()
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, event)
+	if len(o.events) > o.size {
+		o.events = o.events[len(o.events)-o.size:]
+	}
+}
+
+// OnTerminate implements Observer. RingBufferObserver only retains transitions.
+func (o *RingBufferObserver) OnTerminate(err error) {}
+
+// Events returns a copy of the transitions currently held in the ring buffer.
+func (o *RingBufferObserver) Events() []TransitionEvent {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	out := make([]TransitionEvent, len(o.events))
+	copy(out, o.events)
+	return out
+}
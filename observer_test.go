@@ -0,0 +1,93 @@
+package fsm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func observerFixStateA() (State, error) {
+	return observerFixStateB, nil
+}
+
+func observerFixStateB() (State, error) {
+	return nil, nil
+}
+
+func TestAddObserverNotifiesStartTransitionsAndTerminate(t *testing.T) {
+	m := NewMachine()
+	ring := NewRingBufferObserver(10)
+	m.AddObserver(ring)
+
+	if err := m.Run(observerFixStateA); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	events := ring.Events()
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3 (start->A, A->B, B->end): %+v", len(events), events)
+	}
+	if events[0].From != startID || events[0].To != "observerFixStateA" {
+		t.Fatalf("events[0] = %+v, want start -> observerFixStateA", events[0])
+	}
+	if events[1].From != "observerFixStateA" || events[1].To != "observerFixStateB" {
+		t.Fatalf("events[1] = %+v, want observerFixStateA -> observerFixStateB", events[1])
+	}
+	if events[2].From != "observerFixStateB" || events[2].To != endID {
+		t.Fatalf("events[2] = %+v, want observerFixStateB -> end", events[2])
+	}
+}
+
+func TestRingBufferObserverEvictsOldestBeyondSize(t *testing.T) {
+	o := NewRingBufferObserver(2)
+	o.OnTransition("A", "B", 1, nil)
+	o.OnTransition("B", "C", 2, nil)
+	o.OnTransition("C", "D", 3, nil)
+
+	events := o.Events()
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if events[0].From != "B" || events[1].From != "C" {
+		t.Fatalf("events = %+v, want the two most recent transitions (B->C, C->D)", events)
+	}
+}
+
+func TestRingBufferObserverRecordsError(t *testing.T) {
+	o := NewRingBufferObserver(1)
+	o.OnTransition("A", "end", 1, errors.New("boom"))
+
+	events := o.Events()
+	if len(events) != 1 || events[0].Err != "boom" {
+		t.Fatalf("events = %+v, want a single event with Err = %q", events, "boom")
+	}
+}
+
+func TestJSONObserverEncodesOneLinePerTransition(t *testing.T) {
+	var buf bytes.Buffer
+	o := NewJSONObserver(&buf)
+
+	o.OnTransition("A", "B", 1, nil)
+	o.OnTransition("B", "end", 2, errors.New("boom"))
+
+	var events []TransitionEvent
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var e TransitionEvent
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		events = append(events, e)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("got %d decoded events, want 2: %+v", len(events), events)
+	}
+	if events[0].From != "A" || events[0].To != "B" || events[0].Err != "" {
+		t.Fatalf("events[0] = %+v, want {A B 1 \"\"}", events[0])
+	}
+	if events[1].Err != "boom" {
+		t.Fatalf("events[1].Err = %q, want %q", events[1].Err, "boom")
+	}
+}
@@ -0,0 +1,93 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func timeoutFixSlowState() (State, error) {
+	time.Sleep(200 * time.Millisecond)
+	return nil, nil
+}
+
+func TestRunContextCancellationDuringTimeoutRaceDoesNotRecordCompletion(t *testing.T) {
+	m := NewMachine()
+	m.WithStateTimeout("timeoutFixSlowState", time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	err := m.RunContext(ctx, timeoutFixSlowState)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("RunContext() error = %v, want context.Canceled", err)
+	}
+
+	edges := m.adjacencyMap["timeoutFixSlowState"]
+	if _, gotEnd := edges[endID]; gotEnd {
+		t.Fatalf("adjacencyMap recorded timeoutFixSlowState -> %q, want no completion edge for a canceled run: %v", endID, edges)
+	}
+	if _, gotErr := edges[errorID]; !gotErr {
+		t.Fatalf("adjacencyMap = %v, want an edge to %q recording the aborted run", edges, errorID)
+	}
+}
+
+func TestWithStateTimeoutReturnsErrStateTimeoutWhenStateOutlivesDeadline(t *testing.T) {
+	m := NewMachine()
+	m.WithStateTimeout("timeoutFixSlowState", 20*time.Millisecond)
+
+	err := m.Run(timeoutFixSlowState)
+	if !errors.Is(err, errStateTimeout) {
+		t.Fatalf("Run() error = %v, want errStateTimeout", err)
+	}
+
+	edges := m.adjacencyMap["timeoutFixSlowState"]
+	if _, gotTimeout := edges[timeoutID]; !gotTimeout {
+		t.Fatalf("adjacencyMap = %v, want an edge to %q recording the timeout", edges, timeoutID)
+	}
+}
+
+func pauseFixStateA() (State, error) {
+	return nil, nil
+}
+
+func TestPauseBlocksRunUntilResumeIsCalled(t *testing.T) {
+	m := NewMachine()
+	m.Pause()
+
+	done := make(chan error, 1)
+	go func() { done <- m.Run(pauseFixStateA) }()
+
+	select {
+	case <-done:
+		t.Fatalf("Run() returned before Resume was called")
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	m.Resume()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Run() did not return after Resume")
+	}
+}
+
+func TestRunContextStopsBetweenTransitionsWhenCtxIsCanceled(t *testing.T) {
+	m := NewMachine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := m.RunContext(ctx, pauseFixStateA)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("RunContext() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,186 @@
+package fsm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Snapshot captures everything needed to resume a finite-state machine in a
+// new process: the name of the state it is currently in, the step count,
+// and the transition graph recorded so far. Args is reserved for
+// serialized per-state data in machines that carry it, such as EventMachine
+// args; the self-transitioning finiteStateMachine carries no per-state data
+// and always leaves it nil.
+type Snapshot struct {
+	CurrentState string                       `json:"currentState"`
+	Step         int64                        `json:"step"`
+	Args         []byte                       `json:"args,omitempty"`
+	Adjacency    map[string]map[string][]int64 `json:"adjacency"`
+}
+
+// StateRegistry maps stable string names to State functions. Since Go
+// cannot serialize a function pointer, a machine's current state is
+// persisted in a Snapshot by name, and Resume uses a StateRegistry to
+// resolve that name back to the State function that handles it.
+type StateRegistry struct {
+	mu     sync.Mutex
+	states map[string]State
+}
+
+// NewStateRegistry initializes and returns a new, empty StateRegistry.
+func NewStateRegistry() *StateRegistry {
+	return &StateRegistry{states: make(map[string]State)}
+}
+
+// Register associates name with the State function that handles it.
+// Registering a name a second time replaces its State function.
+func (r *StateRegistry) Register(name string, fn State) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.states[name] = fn
+}
+
+// Lookup returns the State function registered under name, and whether one
+// was found.
+func (r *StateRegistry) Lookup(name string) (State, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn, ok := r.states[name]
+	return fn, ok
+}
+
+// Store persists and loads Snapshots so a finite-state machine can survive
+// process restarts. FileStore and MemoryStore are the built-in
+// implementations.
+type Store interface {
+	Save(Snapshot) error
+	Load() (Snapshot, error)
+}
+
+// FileStore is a Store that persists a single Snapshot as JSON at a file path.
+type FileStore struct {
+	path string
+}
+
+// NewFileStore returns a FileStore that saves and loads Snapshots at path.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Save implements Store by writing snap to the FileStore's path as JSON.
+func (s *FileStore) Save(snap Snapshot) error {
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, b, 0o644)
+}
+
+// Load implements Store by reading and unmarshaling the JSON Snapshot at the
+// FileStore's path.
+func (s *FileStore) Load() (Snapshot, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return Snapshot{}, err
+	}
+
+	return snap, nil
+}
+
+// MemoryStore is a Store that keeps the most recently saved Snapshot in
+// memory, useful in tests.
+type MemoryStore struct {
+	mu   sync.Mutex
+	snap Snapshot
+	has  bool
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Save implements Store by replacing the stored Snapshot.
+func (s *MemoryStore) Save(snap Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snap = snap
+	s.has = true
+	return nil
+}
+
+// Load implements Store by returning the most recently saved Snapshot. Load
+// returns an error if Save has never been called.
+func (s *MemoryStore) Load() (Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.has {
+		return Snapshot{}, errors.New("fsm: memory store is empty")
+	}
+	return s.snap, nil
+}
+
+// Checkpoint configures the machine to save a Snapshot to store after every
+// transition, mirroring how durable workflow runners handle interruption:
+// if the process is killed, Resume can pick the machine back up from the
+// last committed state.
+func (fsm *finiteStateMachine) Checkpoint(store Store) {
+	fsm.mu.Lock()
+	defer fsm.mu.Unlock()
+	fsm.store = store
+}
+
+// Snapshot captures the finite-state machine's current state name, step
+// count, and recorded transition graph so it can later be handed to Resume.
+// Snapshot may be called safely from a goroutine other than the one
+// running the machine.
+func (fsm *finiteStateMachine) Snapshot() (Snapshot, error) {
+	fsm.mu.Lock()
+	defer fsm.mu.Unlock()
+
+	adjacency := make(map[string]map[string][]int64, len(fsm.adjacencyMap))
+	for vertex, edges := range fsm.adjacencyMap {
+		edgeCopy := make(map[string][]int64, len(edges))
+		for edge, steps := range edges {
+			stepsCopy := make([]int64, len(steps))
+			copy(stepsCopy, steps)
+			edgeCopy[edge] = stepsCopy
+		}
+		adjacency[vertex] = edgeCopy
+	}
+
+	return Snapshot{
+		CurrentState: fsm.currentStateName,
+		Step:         fsm.step,
+		Adjacency:    adjacency,
+	}, nil
+}
+
+// Resume rebuilds a finite-state machine from snap, resolving its
+// CurrentState through registry. The returned machine is primed to
+// continue: call Run(nil) on it to resume execution from where the
+// Snapshot was taken.
+func Resume(snap Snapshot, registry *StateRegistry) (*finiteStateMachine, error) {
+	start, ok := registry.Lookup(snap.CurrentState)
+	if !ok {
+		return nil, fmt.Errorf("fsm: no state registered with name %q", snap.CurrentState)
+	}
+
+	machine := NewMachine()
+	machine.start = start
+	machine.currentStateName = snap.CurrentState
+	machine.step = snap.Step
+	if snap.Adjacency != nil {
+		machine.adjacencyMap = snap.Adjacency
+	}
+
+	return machine, nil
+}
@@ -0,0 +1,113 @@
+package fsm
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// recordingStore saves every Snapshot it is given, in order, so a test can
+// inspect what Checkpoint persisted after each transition.
+type recordingStore struct {
+	snaps []Snapshot
+}
+
+func (s *recordingStore) Save(snap Snapshot) error {
+	s.snaps = append(s.snaps, snap)
+	return nil
+}
+
+func (s *recordingStore) Load() (Snapshot, error) {
+	return s.snaps[len(s.snaps)-1], nil
+}
+
+var checkpointFixStepB int
+
+func checkpointFixStateA() (State, error) {
+	return checkpointFixStateB, nil
+}
+
+func checkpointFixStateB() (State, error) {
+	checkpointFixStepB++
+	return checkpointFixStateC, nil
+}
+
+func checkpointFixStateC() (State, error) {
+	return nil, nil
+}
+
+func TestCheckpointRecordsTheStateBeingEnteredNotTheOneThatJustRan(t *testing.T) {
+	m := NewMachine()
+	store := &recordingStore{}
+	m.Checkpoint(store)
+
+	if err := m.Run(checkpointFixStateA); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(store.snaps) != 4 {
+		t.Fatalf("got %d checkpoints, want 4 (start, A->B, B->C, C->end): %+v", len(store.snaps), store.snaps)
+	}
+
+	// The checkpoint saved as the machine transitions from A to B must
+	// record "B", the state it is entering, not "A", the state it just
+	// finished. Otherwise Resume re-invokes A a second time.
+	if got := store.snaps[1].CurrentState; got != "checkpointFixStateB" {
+		t.Fatalf("checkpoint taken at the A->B transition has CurrentState = %q, want %q", got, "checkpointFixStateB")
+	}
+
+	registry := NewStateRegistry()
+	registry.Register("checkpointFixStateB", checkpointFixStateB)
+	registry.Register("checkpointFixStateC", checkpointFixStateC)
+
+	resumed, err := Resume(store.snaps[1], registry)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+
+	before := checkpointFixStepB
+	if err := resumed.Run(nil); err != nil {
+		t.Fatalf("resumed Run() error = %v", err)
+	}
+	if checkpointFixStepB != before+1 {
+		t.Fatalf("checkpointFixStateB ran %d times after resuming from its own checkpoint, want 1", checkpointFixStepB-before)
+	}
+}
+
+func TestResumeReturnsErrorForUnregisteredCurrentState(t *testing.T) {
+	snap := Snapshot{CurrentState: "nowhere"}
+	registry := NewStateRegistry()
+
+	if _, err := Resume(snap, registry); err == nil {
+		t.Fatalf("Resume() error = nil, want an error for a CurrentState with no registered State function")
+	}
+}
+
+func TestFileStoreSavesAndLoadsASnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	store := NewFileStore(path)
+
+	want := Snapshot{
+		CurrentState: "checkpointFixStateB",
+		Step:         3,
+		Adjacency:    map[string]map[string][]int64{"A": {"B": {1}}},
+	}
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got.CurrentState != want.CurrentState || got.Step != want.Step {
+		t.Fatalf("Load() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMemoryStoreLoadErrorsWhenNothingSaved(t *testing.T) {
+	store := NewMemoryStore()
+
+	if _, err := store.Load(); err == nil {
+		t.Fatalf("Load() error = nil, want an error for an empty MemoryStore")
+	}
+}
@@ -0,0 +1,139 @@
+package fsm
+
+import (
+	"context"
+	"fmt"
+)
+
+// Transition declares a single legal edge that an EventMachine may take: on
+// Event while in state From, if Guard allows it, the machine moves to state
+// To and runs Action.
+type Transition struct {
+	From  string
+	Event string
+	To    string
+
+	// Guard decides whether this Transition may fire. A nil Guard always
+	// allows the transition.
+	Guard func(ctx context.Context, args any) bool
+
+	// Action runs after the transition is taken and before the new state's
+	// entry hook. An error returned from Action is returned from Fire and
+	// the machine does not move to To.
+	Action func(ctx context.Context, args any) error
+}
+
+// TransitionTable is an ordered declaration of the Transitions an
+// EventMachine is allowed to take. On each Fire, the machine walks the
+// table in declared order and takes the first Transition whose From and
+// Event match the current state and fired event, and whose Guard passes.
+type TransitionTable []Transition
+
+// ErrNoTransition is returned by Fire when an EventMachine has a
+// TransitionTable installed and no declared Transition matches the current
+// state and fired event. It lets callers distinguish an illegal event from
+// a state handler error.
+type ErrNoTransition struct {
+	From  string
+	Event string
+}
+
+// Error implements the error interface.
+func (e *ErrNoTransition) Error() string {
+	return fmt.Sprintf("fsm: no transition from state %q on event %q", e.From, e.Event)
+}
+
+// UseTransitionTable installs table on the machine. Once installed, Fire
+// validates events against the table instead of trusting the nextState
+// returned by a registered EventStateFunc.
+func (m *EventMachine) UseTransitionTable(table TransitionTable) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.table = table
+}
+
+// OnEnter registers a hook run when the machine transitions into state,
+// after Action has succeeded. Registering a second hook for the same state
+// replaces the first.
+func (m *EventMachine) OnEnter(state string, fn func(ctx context.Context, args any)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.entryHooks == nil {
+		m.entryHooks = make(map[string]func(ctx context.Context, args any))
+	}
+	m.entryHooks[state] = fn
+}
+
+// OnExit registers a hook run when the machine transitions out of state,
+// before Action runs. Registering a second hook for the same state replaces
+// the first.
+func (m *EventMachine) OnExit(state string, fn func(ctx context.Context, args any)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.exitHooks == nil {
+		m.exitHooks = make(map[string]func(ctx context.Context, args any))
+	}
+	m.exitHooks[state] = fn
+}
+
+// fireTable drives the machine using its installed TransitionTable instead
+// of a registered EventStateFunc. See Fire for the hook ordering contract.
+func (m *EventMachine) fireTable(ctx context.Context, event string, args any) error {
+	m.mu.Lock()
+	from := m.current
+	table := m.table
+	exit := m.exitHooks[from]
+	before := append([]BeforeHook(nil), m.before...)
+	after := append([]AfterHook(nil), m.after...)
+	m.mu.Unlock()
+
+	for _, hook := range before {
+		hook(ctx, from, event, args)
+	}
+
+	var matched *Transition
+	for i := range table {
+		t := &table[i]
+		if t.From != from || t.Event != event {
+			continue
+		}
+		if t.Guard == nil || t.Guard(ctx, args) {
+			matched = t
+			break
+		}
+	}
+
+	if matched == nil {
+		err := &ErrNoTransition{From: from, Event: event}
+		for _, hook := range after {
+			hook(ctx, from, from, args, err)
+		}
+		return err
+	}
+
+	if exit != nil {
+		exit(ctx, args)
+	}
+
+	var err error
+	if matched.Action != nil {
+		err = matched.Action(ctx, args)
+	}
+
+	if err == nil {
+		m.mu.Lock()
+		m.current = matched.To
+		entry := m.entryHooks[matched.To]
+		m.mu.Unlock()
+
+		if entry != nil {
+			entry(ctx, args)
+		}
+	}
+
+	for _, hook := range after {
+		hook(ctx, from, matched.To, args, err)
+	}
+
+	return err
+}
@@ -0,0 +1,99 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFireTableTakesFirstMatchingTransitionWithPassingGuard(t *testing.T) {
+	m := NewEventMachine("A")
+	m.UseTransitionTable(TransitionTable{
+		{From: "A", Event: "go", To: "Fast", Guard: func(ctx context.Context, args any) bool { return false }},
+		{From: "A", Event: "go", To: "Slow", Guard: func(ctx context.Context, args any) bool { return true }},
+	})
+
+	if err := m.Fire(context.Background(), "go", nil); err != nil {
+		t.Fatalf("Fire() error = %v", err)
+	}
+	if m.Current() != "Slow" {
+		t.Fatalf("Current() = %q, want %q (first transition whose Guard passes)", m.Current(), "Slow")
+	}
+}
+
+func TestFireTableReturnsErrNoTransitionWhenNoTransitionMatches(t *testing.T) {
+	m := NewEventMachine("A")
+	m.UseTransitionTable(TransitionTable{
+		{From: "A", Event: "go", To: "B"},
+	})
+
+	err := m.Fire(context.Background(), "other", nil)
+	var noTransition *ErrNoTransition
+	if !errors.As(err, &noTransition) {
+		t.Fatalf("Fire() error = %v, want *ErrNoTransition", err)
+	}
+	if noTransition.From != "A" || noTransition.Event != "other" {
+		t.Fatalf("ErrNoTransition = %+v, want {From: A, Event: other}", noTransition)
+	}
+	if m.Current() != "A" {
+		t.Fatalf("Current() = %q, want %q (no transition must not move the state)", m.Current(), "A")
+	}
+}
+
+func TestFireTableReturnsErrNoTransitionWhenAllGuardsFail(t *testing.T) {
+	m := NewEventMachine("A")
+	m.UseTransitionTable(TransitionTable{
+		{From: "A", Event: "go", To: "B", Guard: func(ctx context.Context, args any) bool { return false }},
+	})
+
+	var noTransition *ErrNoTransition
+	if err := m.Fire(context.Background(), "go", nil); !errors.As(err, &noTransition) {
+		t.Fatalf("Fire() error = %v, want *ErrNoTransition", err)
+	}
+}
+
+func TestFireTableRunsActionAndSkipsTransitionOnActionError(t *testing.T) {
+	wantErr := errors.New("boom")
+	var actionRan bool
+	m := NewEventMachine("A")
+	m.UseTransitionTable(TransitionTable{
+		{From: "A", Event: "go", To: "B", Action: func(ctx context.Context, args any) error {
+			actionRan = true
+			return wantErr
+		}},
+	})
+
+	if err := m.Fire(context.Background(), "go", nil); !errors.Is(err, wantErr) {
+		t.Fatalf("Fire() error = %v, want %v", err, wantErr)
+	}
+	if !actionRan {
+		t.Fatalf("Action did not run")
+	}
+	if m.Current() != "A" {
+		t.Fatalf("Current() = %q, want %q (an Action error must not transition the state)", m.Current(), "A")
+	}
+}
+
+func TestOnEnterAndOnExitRunAroundATransition(t *testing.T) {
+	var calls []string
+	m := NewEventMachine("A")
+	m.UseTransitionTable(TransitionTable{
+		{From: "A", Event: "go", To: "B"},
+	})
+	m.OnExit("A", func(ctx context.Context, args any) { calls = append(calls, "exit:A") })
+	m.OnEnter("B", func(ctx context.Context, args any) { calls = append(calls, "enter:B") })
+
+	if err := m.Fire(context.Background(), "go", nil); err != nil {
+		t.Fatalf("Fire() error = %v", err)
+	}
+
+	want := []string{"exit:A", "enter:B"}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("calls[%d] = %q, want %q", i, calls[i], want[i])
+		}
+	}
+}
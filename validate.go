@@ -0,0 +1,180 @@
+package fsm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/austingebauer/go-fsm/graph"
+)
+
+// Severity describes how serious a Diagnostic is.
+type Severity int
+
+const (
+	// SeverityWarning flags a shape that is suspicious but not necessarily wrong.
+	SeverityWarning Severity = iota
+	// SeverityError flags a shape that is almost certainly a mistake.
+	SeverityError
+)
+
+// String returns a human-readable name for the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	default:
+		return "warning"
+	}
+}
+
+// Diagnostic describes a single issue found by Validate.
+type Diagnostic struct {
+	Severity Severity
+	State    string
+	Message  string
+}
+
+// String renders the diagnostic as "severity: state: message".
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Severity, d.State, d.Message)
+}
+
+// Validate performs static analysis on the machine's installed
+// TransitionTable — the declared structure, not a runtime history — so it
+// can be called before the machine is ever fired. It returns a Diagnostic
+// for each of:
+//
+//   - a state unreachable from the initial state
+//   - a state with no outgoing transitions that isn't StateEnd or marked
+//     terminal via MarkTerminal
+//   - a cycle of states, including a single state with a self-transition,
+//     with no transition leaving it
+//   - a duplicate transition: more than one declared Transition sharing the
+//     same From state, Event, and To state — distinct To states for the same
+//     (From, Event), such as Guard-differentiated branches, are the intended
+//     way to model conditional transitions and are not flagged
+//
+// Validate requires UseTransitionTable to have been called; it returns no
+// diagnostics for a machine still driven by RegisterState/EventStateFunc.
+func (m *EventMachine) Validate() []Diagnostic {
+	m.mu.Lock()
+	table := append(TransitionTable(nil), m.table...)
+	initial := m.initial
+	terminal := make(map[string]bool, len(m.terminal))
+	for name := range m.terminal {
+		terminal[name] = true
+	}
+	m.mu.Unlock()
+
+	if table == nil {
+		return nil
+	}
+
+	g := graph.New()
+	g.AddVertex(initial)
+	for _, t := range table {
+		g.AddEdge(t.From, t.To)
+	}
+
+	var diagnostics []Diagnostic
+
+	reachable := make(map[string]bool)
+	for _, v := range g.BFS(initial) {
+		reachable[v] = true
+	}
+
+	for _, vertex := range g.Vertices() {
+		if vertex == StateEnd {
+			continue
+		}
+
+		if vertex != initial && !reachable[vertex] {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityError,
+				State:    vertex,
+				Message:  "state is unreachable from the initial state",
+			})
+		}
+
+		if len(g.Edges(vertex)) == 0 && !terminal[vertex] {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityWarning,
+				State:    vertex,
+				Message:  "state has no outgoing transitions and is not StateEnd",
+			})
+		}
+	}
+
+	for _, component := range g.SCC() {
+		if len(component) == 1 && !selfLoop(g, component[0]) {
+			// A singleton component with no self-transition is just a
+			// trivial, acyclic vertex, not a cycle.
+			continue
+		}
+
+		if !g.HasExit(component) {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityError,
+				State:    component[0],
+				Message:  fmt.Sprintf("cycle %v has no transition leaving it", component),
+			})
+		}
+	}
+
+	diagnostics = append(diagnostics, duplicateTransitions(table)...)
+
+	return diagnostics
+}
+
+// selfLoop reports whether vertex has a transition to itself.
+func selfLoop(g *graph.Graph, vertex string) bool {
+	for _, edge := range g.Edges(vertex) {
+		if edge == vertex {
+			return true
+		}
+	}
+	return false
+}
+
+// duplicateTransitions returns a Diagnostic for every (From, Event, To)
+// tuple declared by more than one Transition in table, sorted by From, then
+// Event, then To for deterministic output. Transitions that share a From and Event
+// but disagree on To — the Guard-differentiated branching pattern
+// UseTransitionTable supports — are distinct tuples and are not flagged.
+func duplicateTransitions(table TransitionTable) []Diagnostic {
+	type key struct {
+		from, event, to string
+	}
+
+	counts := make(map[key]int)
+	for _, t := range table {
+		counts[key{t.From, t.Event, t.To}]++
+	}
+
+	var keys []key
+	for k, count := range counts {
+		if count > 1 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].from != keys[j].from {
+			return keys[i].from < keys[j].from
+		}
+		if keys[i].event != keys[j].event {
+			return keys[i].event < keys[j].event
+		}
+		return keys[i].to < keys[j].to
+	})
+
+	diagnostics := make([]Diagnostic, 0, len(keys))
+	for _, k := range keys {
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity: SeverityWarning,
+			State:    k.from,
+			Message:  fmt.Sprintf("event %q has %d transitions declared from this state to %q", k.event, counts[k], k.to),
+		})
+	}
+
+	return diagnostics
+}
@@ -0,0 +1,114 @@
+package fsm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestValidateSelfLoopCycleHasNoExit(t *testing.T) {
+	m := NewEventMachine("A")
+	m.UseTransitionTable(TransitionTable{
+		{From: "A", Event: "spin", To: "A"},
+	})
+
+	diagnostics := m.Validate()
+
+	var found bool
+	for _, d := range diagnostics {
+		if d.State == "A" && d.Severity == SeverityError {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Validate() = %v, want a diagnostic flagging the self-loop on A as a cycle with no exit", diagnostics)
+	}
+}
+
+func TestValidateSelfLoopWithExitIsNotFlagged(t *testing.T) {
+	m := NewEventMachine("A")
+	m.UseTransitionTable(TransitionTable{
+		{From: "A", Event: "spin", To: "A"},
+		{From: "A", Event: "leave", To: "B"},
+	})
+
+	for _, d := range m.Validate() {
+		if d.State == "A" {
+			t.Fatalf("Validate() flagged A, but it has a transition leaving its self-loop: %v", d)
+		}
+	}
+}
+
+func TestValidateDuplicateTransition(t *testing.T) {
+	m := NewEventMachine("A")
+	m.UseTransitionTable(TransitionTable{
+		{From: "A", Event: "go", To: "B"},
+		{From: "A", Event: "go", To: "B"},
+	})
+
+	var found bool
+	for _, d := range m.Validate() {
+		if d.State == "A" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Validate() did not flag the duplicate 'go' -> B transition declared twice from A")
+	}
+}
+
+func TestValidateGuardedBranchesAreNotDuplicates(t *testing.T) {
+	m := NewEventMachine("A")
+	m.UseTransitionTable(TransitionTable{
+		{From: "A", Event: "go", To: "Fast", Guard: func(ctx context.Context, args any) bool { return true }},
+		{From: "A", Event: "go", To: "Slow", Guard: func(ctx context.Context, args any) bool { return false }},
+	})
+
+	for _, d := range m.Validate() {
+		if d.State == "A" {
+			t.Fatalf("Validate() flagged A, but its two 'go' transitions branch to distinct states via Guard: %v", d)
+		}
+	}
+}
+
+func TestValidateNoTableReturnsNoDiagnostics(t *testing.T) {
+	m := NewEventMachine("A")
+	m.RegisterState("A", func(ctx context.Context, args any) (string, any, error) {
+		return StateEnd, nil, nil
+	})
+
+	if diagnostics := m.Validate(); diagnostics != nil {
+		t.Fatalf("Validate() = %v, want nil for a machine with no installed TransitionTable", diagnostics)
+	}
+}
+
+func TestValidateTerminalStateWithNoOutgoingTransitionsIsNotFlagged(t *testing.T) {
+	m := NewEventMachine("A")
+	m.UseTransitionTable(TransitionTable{
+		{From: "A", Event: "go", To: "Dead"},
+	})
+	m.MarkTerminal("Dead")
+
+	for _, d := range m.Validate() {
+		if d.State == "Dead" {
+			t.Fatalf("Validate() flagged terminal state Dead for having no outgoing transitions: %v", d)
+		}
+	}
+}
+
+func TestValidateUnreachableState(t *testing.T) {
+	m := NewEventMachine("A")
+	m.UseTransitionTable(TransitionTable{
+		{From: "A", Event: "go", To: "B"},
+		{From: "C", Event: "go", To: "B"},
+	})
+
+	var found bool
+	for _, d := range m.Validate() {
+		if d.State == "C" && d.Severity == SeverityError {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Validate() did not flag C as unreachable from the initial state A")
+	}
+}